@@ -0,0 +1,134 @@
+package input
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightningnetwork/lnd/lntypes"
+)
+
+const (
+	// P2PKHSigScriptSize is the maximum size, in bytes, of the
+	// scriptSig that spends a bare P2PKH output:
+	//	OP_DATA_73 <72 byte DER sig + sighash byte> OP_DATA_33
+	//	<33 byte compressed pubkey>
+	P2PKHSigScriptSize = 1 + 73 + 1 + 33
+
+	// P2PKHInputSize is the maximum size, in bytes, of a non-witness
+	// transaction input spending a bare P2PKH output:
+	//	outpoint (36) + scriptSig varint (1) + scriptSig
+	//	(P2PKHSigScriptSize) + sequence (4)
+	//
+	// Unlike a segwit input, none of this is eligible for the witness
+	// discount, so every byte here is charged at the full weight factor
+	// of 4.
+	P2PKHInputSize = 36 + 1 + P2PKHSigScriptSize + 4
+)
+
+// KeySpender crafts the final, non-witness scriptSig for an input that is
+// spent purely by proving ownership of a single private key. It is the
+// scriptSig analogue of WitnessGenerator for legacy, pre-segwit output
+// types such as P2PKH. hashType must be the same sighash type the signature
+// was produced with, since it's appended verbatim to the DER signature.
+type KeySpender func(sig Signature, pubKey *btcec.PublicKey,
+	hashType txscript.SigHashType) ([]byte, error)
+
+// P2PKHKeySpender is the KeySpender for bare P2PKH outputs. It assembles the
+// classic <sig> <pubkey> scriptSig.
+func P2PKHKeySpender(sig Signature, pubKey *btcec.PublicKey,
+	hashType txscript.SigHashType) ([]byte, error) {
+
+	builder := txscript.NewScriptBuilder()
+	builder.AddData(append(sig.Serialize(), byte(hashType)))
+	builder.AddData(pubKey.SerializeCompressed())
+
+	return builder.Script()
+}
+
+// P2PKHScript returns the bare P2PKH pkScript for the given address:
+//
+//	OP_DUP OP_HASH160 <pubkey hash> OP_EQUALVERIFY OP_CHECKSIG
+func P2PKHScript(addr *btcutil.AddressPubKeyHash) ([]byte, error) {
+	return txscript.PayToAddrScript(addr)
+}
+
+// payToPubKeyHash is a WitnessType that spends a bare P2PKH output. Despite
+// its name (shared with the rest of this package's WitnessType
+// implementations for consistency), it populates the SignatureScript of the
+// resulting Script rather than its Witness, since P2PKH predates segwit.
+type payToPubKeyHash struct {
+	addr *btcutil.AddressPubKeyHash
+}
+
+// PayToPubKeyHash returns a WitnessType that spends the bare P2PKH output
+// controlled by addr.
+func PayToPubKeyHash(addr *btcutil.AddressPubKeyHash) WitnessType {
+	return &payToPubKeyHash{addr: addr}
+}
+
+// String returns a human readable version of the WitnessType.
+func (p *payToPubKeyHash) String() string {
+	return "pay-to-pubkey-hash"
+}
+
+// WitnessGenerator returns a function that can be used to generate the
+// scriptSig that spends a bare P2PKH output. The returned Script carries no
+// witness data.
+func (p *payToPubKeyHash) WitnessGenerator(signer Signer,
+	descriptor *SignDescriptor) WitnessGenerator {
+
+	return func(tx *wire.MsgTx, _ *txscript.TxSigHashes,
+		inputIndex int) (*Script, error) {
+
+		descCopy := *descriptor
+		desc := &descCopy
+		desc.InputIndex = inputIndex
+
+		sig, err := signer.SignOutputRaw(tx, desc)
+		if err != nil {
+			return nil, fmt.Errorf("unable to generate sig: %w",
+				err)
+		}
+
+		sigScript, err := P2PKHKeySpender(
+			sig, desc.KeyDesc.PubKey, desc.HashType,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("unable to generate "+
+				"sigScript: %w", err)
+		}
+
+		return &Script{SigScript: sigScript}, nil
+	}
+}
+
+// SizeUpperBound returns the maximum weight of the scriptSig required to
+// spend a bare P2PKH output, i.e. P2PKHSigScriptSize bytes each charged at
+// the non-witness weight factor of 4. The bool return is always false: P2PKH
+// is never a nested P2SH output.
+func (p *payToPubKeyHash) SizeUpperBound() (lntypes.WeightUnit, bool, error) {
+	return lntypes.WeightUnit(4 * P2PKHSigScriptSize), false, nil
+}
+
+// AddWeightEstimation adds the estimated size of the scriptSig to the given
+// weight estimator.
+func (p *payToPubKeyHash) AddWeightEstimation(e *TxWeightEstimator) error {
+	e.AddP2PKHInput()
+
+	return nil
+}
+
+// AddP2PKHInput updates the weight estimate to account for an additional
+// input spending a bare P2PKH output. Since a legacy input carries no
+// witness data, its entire size is charged at the base, non-discounted
+// weight factor rather than the one AddP2WKHInput and its segwit siblings
+// use for their witness bytes.
+func (twe *TxWeightEstimator) AddP2PKHInput() *TxWeightEstimator {
+	twe.inputSize += P2PKHInputSize
+	twe.inputCount++
+
+	return twe
+}
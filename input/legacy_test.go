@@ -0,0 +1,93 @@
+package input
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightningnetwork/lnd/keychain"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPayToPubKeyHashSweep asserts that the P2PKH WitnessType produces a
+// SignatureScript (rather than a Witness) that successfully spends a bare
+// P2PKH output.
+func TestPayToPubKeyHashSweep(t *testing.T) {
+	privKeys, pubKeys := genKeys(t, 1)
+	privKey, pubKey := privKeys[0], pubKeys[0]
+
+	pubKeyHash := btcutil.Hash160(pubKey.SerializeCompressed())
+	addr, err := btcutil.NewAddressPubKeyHash(
+		pubKeyHash, &chaincfg.RegressionNetParams,
+	)
+	require.NoError(t, err)
+
+	pkScript, err := P2PKHScript(addr)
+	require.NoError(t, err)
+
+	fundTx, op := fundingLegacyTx(t, pkScript)
+	tx := sweepTx(op)
+
+	// Use a non-default hash type to prove the scriptSig's trailing
+	// sighash byte tracks whatever hash type the descriptor actually
+	// carries, rather than a hardcoded one.
+	const hashType = txscript.SigHashAll | txscript.SigHashAnyOneCanPay
+
+	sigHash, err := txscript.CalcSignatureHash(pkScript, hashType, tx, 0)
+	require.NoError(t, err)
+
+	sig := ecdsa.Sign(privKey, sigHash)
+
+	signer := &MockInputSigner{}
+	signer.On("SignOutputRaw", tx, &SignDescriptor{
+		KeyDesc:    keychain.KeyDescriptor{PubKey: pubKey},
+		InputIndex: 0,
+		HashType:   hashType,
+	}).Return(sig, nil)
+
+	witnessType := PayToPubKeyHash(addr)
+	genWitness := witnessType.WitnessGenerator(signer, &SignDescriptor{
+		KeyDesc:  keychain.KeyDescriptor{PubKey: pubKey},
+		HashType: hashType,
+	})
+
+	script, err := genWitness(tx, nil, 0)
+	require.NoError(t, err)
+	require.Empty(t, script.Witness)
+	require.NotEmpty(t, script.SigScript)
+
+	tx.TxIn[0].SignatureScript = script.SigScript
+
+	engine, err := txscript.NewEngine(
+		pkScript, tx, 0, txscript.StandardVerifyFlags, nil, nil,
+		fundTx.TxOut[0].Value, nil,
+	)
+	require.NoError(t, err)
+	require.NoError(t, engine.Execute())
+
+	upperBound, isNestedP2SH, err := witnessType.SizeUpperBound()
+	require.NoError(t, err)
+	require.False(t, isNestedP2SH)
+	require.LessOrEqual(
+		t, uint64(len(script.SigScript)), uint64(upperBound),
+	)
+}
+
+// fundingLegacyTx returns a single output funding transaction paying to the
+// given pkScript, along with the outpoint of that output.
+func fundingLegacyTx(t *testing.T, pkScript []byte) (
+	*wire.MsgTx, wire.OutPoint) {
+
+	t.Helper()
+
+	tx := wire.NewMsgTx(2)
+	tx.AddTxOut(&wire.TxOut{
+		Value:    1_000_000,
+		PkScript: pkScript,
+	})
+
+	return tx, wire.OutPoint{Hash: tx.TxHash(), Index: 0}
+}
@@ -2,6 +2,7 @@ package input
 
 import (
 	"crypto/sha256"
+	"fmt"
 
 	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/btcec/v2/schnorr"
@@ -175,6 +176,30 @@ func (m *MockWitnessType) AddWeightEstimation(e *TxWeightEstimator) error {
 // MockInputSigner is a mock implementation of the Signer interface.
 type MockInputSigner struct {
 	mock.Mock
+
+	// privKeys, when non-nil, switches the MuSig2 surface of
+	// MockInputSigner from testify-mocked calls over to a real
+	// implementation backed by these in-memory keys. A key locator's
+	// Index selects which entry a given MuSig2CreateSession call binds
+	// to. This spares callers that hold more than one local key (tests,
+	// aggregate server-side signers) from having to stub every MuSig2
+	// call individually.
+	privKeys []*btcec.PrivateKey
+
+	sessions *musig2SessionManager
+}
+
+// NewMockInputSignerWithPrivKeys returns a MockInputSigner whose MuSig2
+// methods are implemented directly on top of privKeys instead of requiring
+// per-call mock.On(...) wiring. All other Signer methods remain mocked via
+// the embedded mock.Mock as usual.
+func NewMockInputSignerWithPrivKeys(
+	privKeys []*btcec.PrivateKey) *MockInputSigner {
+
+	return &MockInputSigner{
+		privKeys: privKeys,
+		sessions: newMuSig2SessionManager(),
+	}
 }
 
 // Compile-time constraint to ensure MockInputSigner implements Signer.
@@ -213,12 +238,25 @@ func (m *MockInputSigner) MuSig2CreateSession(version MuSig2Version,
 	tweak *MuSig2Tweaks, pubNonces [][musig2.PubNonceSize]byte,
 	nonces *musig2.Nonces) (*MuSig2SessionInfo, error) {
 
-	args := m.Called(version, locator, pubkey, tweak, pubNonces, nonces)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
+	if m.privKeys == nil {
+		args := m.Called(
+			version, locator, pubkey, tweak, pubNonces, nonces,
+		)
+		if args.Get(0) == nil {
+			return nil, args.Error(1)
+		}
+
+		return args.Get(0).(*MuSig2SessionInfo), args.Error(1)
+	}
+
+	if int(locator.Index) >= len(m.privKeys) {
+		return nil, fmt.Errorf("no private key registered for "+
+			"locator %v", locator)
 	}
 
-	return args.Get(0).(*MuSig2SessionInfo), args.Error(1)
+	return m.sessions.createSession(
+		m.privKeys[locator.Index], version, pubkey, tweak, pubNonces,
+	)
 }
 
 // MuSig2RegisterNonces registers one or more public nonces of other signing
@@ -226,12 +264,16 @@ func (m *MockInputSigner) MuSig2CreateSession(version MuSig2Version,
 func (m *MockInputSigner) MuSig2RegisterNonces(versio MuSig2SessionID,
 	pubNonces [][musig2.PubNonceSize]byte) (bool, error) {
 
-	args := m.Called(versio, pubNonces)
-	if args.Get(0) == nil {
-		return false, args.Error(1)
+	if m.privKeys == nil {
+		args := m.Called(versio, pubNonces)
+		if args.Get(0) == nil {
+			return false, args.Error(1)
+		}
+
+		return args.Bool(0), args.Error(1)
 	}
 
-	return args.Bool(0), args.Error(1)
+	return m.sessions.registerNonces(versio, pubNonces)
 }
 
 // MuSig2Sign creates a partial signature using the local signing key that was
@@ -240,12 +282,16 @@ func (m *MockInputSigner) MuSig2Sign(sessionID MuSig2SessionID,
 	msg [sha256.Size]byte, withSortedKeys bool) (
 	*musig2.PartialSignature, error) {
 
-	args := m.Called(sessionID, msg, withSortedKeys)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
+	if m.privKeys == nil {
+		args := m.Called(sessionID, msg, withSortedKeys)
+		if args.Get(0) == nil {
+			return nil, args.Error(1)
+		}
+
+		return args.Get(0).(*musig2.PartialSignature), args.Error(1)
 	}
 
-	return args.Get(0).(*musig2.PartialSignature), args.Error(1)
+	return m.sessions.sign(sessionID, msg, withSortedKeys)
 }
 
 // MuSig2CombineSig combines the given partial signature(s) with the local one,
@@ -254,17 +300,26 @@ func (m *MockInputSigner) MuSig2CombineSig(sessionID MuSig2SessionID,
 	partialSig []*musig2.PartialSignature) (
 	*schnorr.Signature, bool, error) {
 
-	args := m.Called(sessionID, partialSig)
-	if args.Get(0) == nil {
-		return nil, false, args.Error(2)
+	if m.privKeys == nil {
+		args := m.Called(sessionID, partialSig)
+		if args.Get(0) == nil {
+			return nil, false, args.Error(2)
+		}
+
+		return args.Get(0).(*schnorr.Signature), args.Bool(1),
+			args.Error(2)
 	}
 
-	return args.Get(0).(*schnorr.Signature), args.Bool(1), args.Error(2)
+	return m.sessions.combineSig(sessionID, partialSig)
 }
 
 // MuSig2Cleanup removes a session from memory to free up resources.
 func (m *MockInputSigner) MuSig2Cleanup(sessionID MuSig2SessionID) error {
-	args := m.Called(sessionID)
+	if m.privKeys == nil {
+		args := m.Called(sessionID)
 
-	return args.Error(0)
+		return args.Error(0)
+	}
+
+	return m.sessions.cleanup(sessionID)
 }
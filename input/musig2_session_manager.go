@@ -0,0 +1,163 @@
+package input
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sync"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr/musig2"
+)
+
+// musig2SessionManager tracks the in-memory MuSig2 session state for a
+// Signer implementation that's backed by real private keys (as opposed to
+// one that drives a remote signer or hardware device). It's shared by
+// MockInputSigner's privkey-backed mode and ScriptedInputSigner so both
+// implementations drive an identical session lifecycle rather than each
+// maintaining its own copy of the bookkeeping.
+type musig2SessionManager struct {
+	mu       sync.Mutex
+	sessions map[MuSig2SessionID]*musig2.Session
+}
+
+// newMuSig2SessionManager returns a ready-to-use musig2SessionManager.
+func newMuSig2SessionManager() *musig2SessionManager {
+	return &musig2SessionManager{
+		sessions: make(map[MuSig2SessionID]*musig2.Session),
+	}
+}
+
+// createSession starts a new MuSig2 session for privKey, registering any
+// pubNonces that are already known, and returns the MuSig2SessionInfo the
+// Signer interface expects to hand back to its caller.
+func (m *musig2SessionManager) createSession(privKey *btcec.PrivateKey,
+	version MuSig2Version, pubKeys []*btcec.PublicKey,
+	tweaks *MuSig2Tweaks, pubNonces [][musig2.PubNonceSize]byte) (
+	*MuSig2SessionInfo, error) {
+
+	opts := musig2SessionOpts(version, pubKeys, tweaks)
+	session, err := musig2.NewSession(privKey, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create musig2 session: %w",
+			err)
+	}
+
+	for _, pubNonce := range pubNonces {
+		if _, err := session.RegisterPubNonce(pubNonce); err != nil {
+			return nil, fmt.Errorf("unable to register nonce: %w",
+				err)
+		}
+	}
+
+	publicNonce := session.PublicNonce()
+	sessionID := MuSig2SessionID(sha256.Sum256(publicNonce[:]))
+
+	m.mu.Lock()
+	m.sessions[sessionID] = session
+	m.mu.Unlock()
+
+	return &MuSig2SessionInfo{
+		SessionID:     sessionID,
+		PublicNonce:   publicNonce,
+		HaveAllNonces: len(pubNonces) == len(pubKeys)-1,
+	}, nil
+}
+
+// session looks up the in-memory MuSig2 session for sessionID.
+func (m *musig2SessionManager) session(
+	sessionID MuSig2SessionID) (*musig2.Session, error) {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	session, ok := m.sessions[sessionID]
+	if !ok {
+		return nil, fmt.Errorf("unknown musig2 session: %x", sessionID)
+	}
+
+	return session, nil
+}
+
+// registerNonces registers one or more public nonces of other signing
+// participants for the session identified by sessionID.
+func (m *musig2SessionManager) registerNonces(sessionID MuSig2SessionID,
+	pubNonces [][musig2.PubNonceSize]byte) (bool, error) {
+
+	session, err := m.session(sessionID)
+	if err != nil {
+		return false, err
+	}
+
+	var haveAllNonces bool
+	for _, pubNonce := range pubNonces {
+		haveAllNonces, err = session.RegisterPubNonce(pubNonce)
+		if err != nil {
+			return false, fmt.Errorf("unable to register "+
+				"nonce: %w", err)
+		}
+	}
+
+	return haveAllNonces, nil
+}
+
+// sign creates a partial signature over msg using the session identified by
+// sessionID.
+func (m *musig2SessionManager) sign(sessionID MuSig2SessionID,
+	msg [sha256.Size]byte, withSortedKeys bool) (
+	*musig2.PartialSignature, error) {
+
+	session, err := m.session(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return session.Sign(msg, musig2.WithSortedKeys(withSortedKeys))
+}
+
+// combineSig combines partialSigs with the local partial signature already
+// held by the session identified by sessionID.
+func (m *musig2SessionManager) combineSig(sessionID MuSig2SessionID,
+	partialSigs []*musig2.PartialSignature) (
+	*schnorr.Signature, bool, error) {
+
+	session, err := m.session(sessionID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// A session with no other partial signatures to combine (e.g. a
+	// 1-of-1 ceremony) is already complete as soon as it produces its
+	// own partial signature, so there's nothing further to combine.
+	if len(partialSigs) == 0 {
+		if finalSig := session.FinalSig(); finalSig != nil {
+			return finalSig, true, nil
+		}
+
+		return nil, false, nil
+	}
+
+	var haveAllSigs bool
+	for _, sig := range partialSigs {
+		haveAllSigs, err = session.CombineSig(sig)
+		if err != nil {
+			return nil, false, fmt.Errorf("unable to combine "+
+				"partial sig: %w", err)
+		}
+	}
+
+	if !haveAllSigs {
+		return nil, false, nil
+	}
+
+	return session.FinalSig(), true, nil
+}
+
+// cleanup removes the session identified by sessionID from memory.
+func (m *musig2SessionManager) cleanup(sessionID MuSig2SessionID) error {
+	m.mu.Lock()
+	delete(m.sessions, sessionID)
+	m.mu.Unlock()
+
+	return nil
+}
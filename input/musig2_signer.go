@@ -0,0 +1,276 @@
+package input
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr/musig2"
+	"github.com/lightningnetwork/lnd/keychain"
+)
+
+// MuSig2SignAll drives a full MuSig2 signing ceremony for every private key
+// in privKeys, all of which are assumed to be known locally (e.g. in tests,
+// or for a server-side aggregate signer that co-locates multiple keys). It
+// returns the final, combined schnorr signature over msg.
+func MuSig2SignAll(version MuSig2Version, privKeys []*btcec.PrivateKey,
+	pubKeys []*btcec.PublicKey, tweaks *MuSig2Tweaks,
+	msg [sha256.Size]byte) (*schnorr.Signature, error) {
+
+	if len(privKeys) == 0 {
+		return nil, fmt.Errorf("at least one private key is required")
+	}
+
+	opts := musig2SessionOpts(version, pubKeys, tweaks)
+
+	sessions := make([]*musig2.Session, len(privKeys))
+	for i, privKey := range privKeys {
+		session, err := musig2.NewSession(privKey, opts...)
+		if err != nil {
+			cleanupMuSig2Sessions(sessions)
+
+			return nil, fmt.Errorf("unable to create musig2 "+
+				"session: %w", err)
+		}
+
+		sessions[i] = session
+	}
+
+	// Every session needs to know about the public nonce of every other
+	// session before it's able to produce a partial signature.
+	for i, session := range sessions {
+		for j, otherSession := range sessions {
+			if i == j {
+				continue
+			}
+
+			haveAllNonces, err := session.RegisterPubNonce(
+				otherSession.PublicNonce(),
+			)
+			if err != nil {
+				cleanupMuSig2Sessions(sessions)
+
+				return nil, fmt.Errorf("unable to register "+
+					"nonce: %w", err)
+			}
+
+			if j == len(sessions)-1 && !haveAllNonces {
+				cleanupMuSig2Sessions(sessions)
+
+				return nil, fmt.Errorf("session %d is "+
+					"missing nonces after registering "+
+					"all known participants", i)
+			}
+		}
+	}
+
+	partialSigs := make([]*musig2.PartialSignature, len(sessions))
+	for i, session := range sessions {
+		sig, err := session.Sign(msg)
+		if err != nil {
+			cleanupMuSig2Sessions(sessions)
+
+			return nil, fmt.Errorf("unable to generate partial "+
+				"sig: %w", err)
+		}
+
+		partialSigs[i] = sig
+	}
+
+	combiner := sessions[0]
+
+	var haveAllSigs bool
+	if len(sessions) == 1 {
+		// A single participant ceremony is already complete once it
+		// produces its own partial signature; there's nothing left
+		// to combine.
+		haveAllSigs = combiner.FinalSig() != nil
+	} else {
+		for i, sig := range partialSigs[1:] {
+			var err error
+			haveAllSigs, err = combiner.CombineSig(sig)
+			if err != nil {
+				cleanupMuSig2Sessions(sessions)
+
+				return nil, fmt.Errorf("unable to combine "+
+					"partial sig %d: %w", i+1, err)
+			}
+		}
+	}
+
+	if !haveAllSigs {
+		cleanupMuSig2Sessions(sessions)
+
+		return nil, fmt.Errorf("combined signature is missing " +
+			"partial signatures")
+	}
+
+	finalSig := combiner.FinalSig()
+
+	cleanupMuSig2Sessions(sessions)
+
+	return finalSig, nil
+}
+
+// musig2SessionOpts translates the given tweaks into the session options
+// accepted by musig2.NewSession.
+func musig2SessionOpts(version MuSig2Version, pubKeys []*btcec.PublicKey,
+	tweaks *MuSig2Tweaks) []musig2.SessionOption {
+
+	opts := []musig2.SessionOption{
+		musig2.WithKnownSigners(pubKeys),
+	}
+
+	if tweaks == nil {
+		return opts
+	}
+
+	if len(tweaks.GenericTweaks) > 0 {
+		opts = append(
+			opts, musig2.WithTweaks(tweaks.GenericTweaks...),
+		)
+	}
+
+	switch {
+	case tweaks.TaprootBIP0086Tweak:
+		opts = append(opts, musig2.WithBip86TweakOption())
+
+	case len(tweaks.TaprootTweak) > 0:
+		opts = append(
+			opts, musig2.WithTaprootTweakOption(
+				tweaks.TaprootTweak,
+			),
+		)
+	}
+
+	return opts
+}
+
+// cleanupMuSig2Sessions releases the in-memory state held by every non-nil
+// session in the slice. It's always safe to call, even on a partially
+// initialized slice.
+func cleanupMuSig2Sessions(sessions []*musig2.Session) {
+	// The musig2.Session type doesn't hold any resources that need
+	// explicit releasing beyond garbage collection, but later sessions
+	// are cleared here so a caller that reuses the slice after an error
+	// can't accidentally operate on stale state.
+	for i := range sessions {
+		sessions[i] = nil
+	}
+}
+
+// MuSig2SignAllWithSigner drives a full MuSig2 signing ceremony using the
+// Signer interface, one session per key locator in locators. Unlike
+// MuSig2SignAll, the private keys never leave the Signer implementation;
+// sessions are identified purely by the MuSig2SessionID the Signer hands
+// back from MuSig2CreateSession. This is the variant production code (e.g.
+// an aggregate server-side signer) should use.
+func MuSig2SignAllWithSigner(signer Signer, version MuSig2Version,
+	locators []keychain.KeyLocator, pubKeys []*btcec.PublicKey,
+	tweaks *MuSig2Tweaks, msg [sha256.Size]byte) (*schnorr.Signature,
+	error) {
+
+	if len(locators) == 0 {
+		return nil, fmt.Errorf("at least one key locator is required")
+	}
+
+	sessions := make([]*MuSig2SessionInfo, len(locators))
+	for i, locator := range locators {
+		session, err := signer.MuSig2CreateSession(
+			version, locator, pubKeys, tweaks, nil, nil,
+		)
+		if err != nil {
+			cleanupSignerSessions(signer, sessions)
+
+			return nil, fmt.Errorf("unable to create musig2 "+
+				"session: %w", err)
+		}
+
+		sessions[i] = session
+	}
+
+	for i, session := range sessions {
+		var otherNonces [][musig2.PubNonceSize]byte
+		for j, otherSession := range sessions {
+			if i == j {
+				continue
+			}
+
+			otherNonces = append(
+				otherNonces, otherSession.PublicNonce,
+			)
+		}
+
+		// A 1-of-1 ceremony has no other nonces to register; its
+		// session already reported whether it has all nonces.
+		haveAllNonces := session.HaveAllNonces
+		if len(otherNonces) > 0 {
+			var err error
+			haveAllNonces, err = signer.MuSig2RegisterNonces(
+				session.SessionID, otherNonces,
+			)
+			if err != nil {
+				cleanupSignerSessions(signer, sessions)
+
+				return nil, fmt.Errorf("unable to register "+
+					"nonces for session %d: %w", i, err)
+			}
+		}
+
+		if !haveAllNonces {
+			cleanupSignerSessions(signer, sessions)
+
+			return nil, fmt.Errorf("session %d is missing "+
+				"nonces after registering all known "+
+				"participants", i)
+		}
+	}
+
+	partialSigs := make([]*musig2.PartialSignature, len(sessions))
+	for i, session := range sessions {
+		sig, err := signer.MuSig2Sign(session.SessionID, msg, false)
+		if err != nil {
+			cleanupSignerSessions(signer, sessions)
+
+			return nil, fmt.Errorf("unable to generate partial "+
+				"sig for session %d: %w", i, err)
+		}
+
+		partialSigs[i] = sig
+	}
+
+	finalSig, haveAllSigs, err := signer.MuSig2CombineSig(
+		sessions[0].SessionID, partialSigs[1:],
+	)
+	if err != nil {
+		cleanupSignerSessions(signer, sessions)
+
+		return nil, fmt.Errorf("unable to combine partial sigs: %w",
+			err)
+	}
+
+	if !haveAllSigs {
+		cleanupSignerSessions(signer, sessions)
+
+		return nil, fmt.Errorf("combined signature is missing " +
+			"partial signatures")
+	}
+
+	cleanupSignerSessions(signer, sessions)
+
+	return finalSig, nil
+}
+
+// cleanupSignerSessions calls MuSig2Cleanup on the Signer for every non-nil
+// session in the slice, ignoring any cleanup errors. It's safe to call on a
+// partially initialized slice.
+func cleanupSignerSessions(signer Signer, sessions []*MuSig2SessionInfo) {
+	for _, session := range sessions {
+		if session == nil {
+			continue
+		}
+
+		_ = signer.MuSig2Cleanup(session.SessionID)
+	}
+}
@@ -0,0 +1,112 @@
+package input
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr/musig2"
+	"github.com/lightningnetwork/lnd/keychain"
+	"github.com/stretchr/testify/require"
+)
+
+// genKeys returns numKeys freshly generated private keys along with their
+// corresponding public keys.
+func genKeys(t *testing.T, numKeys int) ([]*btcec.PrivateKey,
+	[]*btcec.PublicKey) {
+
+	t.Helper()
+
+	privKeys := make([]*btcec.PrivateKey, numKeys)
+	pubKeys := make([]*btcec.PublicKey, numKeys)
+	for i := 0; i < numKeys; i++ {
+		privKey, err := btcec.NewPrivateKey()
+		require.NoError(t, err)
+
+		privKeys[i] = privKey
+		pubKeys[i] = privKey.PubKey()
+	}
+
+	return privKeys, pubKeys
+}
+
+// TestMuSig2SignAll asserts that MuSig2SignAll produces a valid combined
+// signature across every local private key.
+func TestMuSig2SignAll(t *testing.T) {
+	privKeys, pubKeys := genKeys(t, 2)
+
+	var msg [32]byte
+	copy(msg[:], []byte("static address sweep test msg!!"))
+
+	sig, err := MuSig2SignAll(0, privKeys, pubKeys, nil, msg)
+	require.NoError(t, err)
+	require.NotNil(t, sig)
+
+	combinedKey, _, _, err := musig2.AggregateKeys(pubKeys, false)
+	require.NoError(t, err)
+	require.True(t, sig.Verify(msg[:], combinedKey.FinalKey))
+}
+
+// TestMuSig2SignAllSingleSigner asserts that a 1-of-1 ceremony, which has no
+// other partial signatures to combine, still produces a valid signature
+// instead of erroring with "missing partial signatures".
+func TestMuSig2SignAllSingleSigner(t *testing.T) {
+	privKeys, pubKeys := genKeys(t, 1)
+
+	var msg [32]byte
+	copy(msg[:], []byte("single signer musig2 test msg!!"))
+
+	sig, err := MuSig2SignAll(0, privKeys, pubKeys, nil, msg)
+	require.NoError(t, err)
+	require.NotNil(t, sig)
+
+	require.True(t, sig.Verify(msg[:], pubKeys[0]))
+}
+
+// TestMockInputSignerWithPrivKeys asserts that the privkey-backed
+// MockInputSigner can complete a full MuSig2 ceremony for two local
+// sessions, driven purely through the Signer interface.
+func TestMockInputSignerWithPrivKeys(t *testing.T) {
+	privKeys, pubKeys := genKeys(t, 2)
+
+	signer := NewMockInputSignerWithPrivKeys(privKeys)
+
+	locators := []keychain.KeyLocator{
+		{Index: 0},
+		{Index: 1},
+	}
+
+	var msg [32]byte
+	copy(msg[:], []byte("static address sweep test msg!!"))
+
+	sig, err := MuSig2SignAllWithSigner(
+		signer, 0, locators, pubKeys, nil, msg,
+	)
+	require.NoError(t, err)
+	require.NotNil(t, sig)
+
+	combinedKey, _, _, err := musig2.AggregateKeys(pubKeys, false)
+	require.NoError(t, err)
+	require.True(t, sig.Verify(msg[:], combinedKey.FinalKey))
+}
+
+// TestMockInputSignerWithPrivKeysSingleSigner asserts that
+// MuSig2SignAllWithSigner also handles the 1-of-1 case, exercising
+// musig2SessionManager.combineSig with zero other partial signatures.
+func TestMockInputSignerWithPrivKeysSingleSigner(t *testing.T) {
+	privKeys, pubKeys := genKeys(t, 1)
+
+	signer := NewMockInputSignerWithPrivKeys(privKeys)
+
+	locators := []keychain.KeyLocator{{Index: 0}}
+
+	var msg [32]byte
+	copy(msg[:], []byte("single signer musig2 test msg!!"))
+
+	sig, err := MuSig2SignAllWithSigner(
+		signer, 0, locators, pubKeys, nil, msg,
+	)
+	require.NoError(t, err)
+	require.NotNil(t, sig)
+
+	require.True(t, sig.Verify(msg[:], pubKeys[0]))
+}
@@ -0,0 +1,281 @@
+package input
+
+import (
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr/musig2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightningnetwork/lnd/keychain"
+	"github.com/stretchr/testify/require"
+)
+
+// TestScriptedInputSignerMuSig2 asserts that two independent
+// ScriptedInputSigner instances, each holding one local key, can complete a
+// full MuSig2 ceremony with each other and that signerA's MuSig2Sign call is
+// observable on its MuSig2SignCh.
+func TestScriptedInputSignerMuSig2(t *testing.T) {
+	locator := keychain.KeyLocator{Family: keychain.KeyFamilyMultiSig}
+
+	privKeys, pubKeys := genKeys(t, 2)
+
+	signerA := NewScriptedInputSigner(
+		map[keychain.KeyLocator]*btcec.PrivateKey{
+			locator: privKeys[0],
+		},
+	)
+	signerB := NewScriptedInputSigner(
+		map[keychain.KeyLocator]*btcec.PrivateKey{
+			locator: privKeys[1],
+		},
+	)
+	t.Cleanup(signerA.Close)
+	t.Cleanup(signerB.Close)
+
+	sessionA, err := signerA.MuSig2CreateSession(
+		0, locator, pubKeys, nil, nil, nil,
+	)
+	require.NoError(t, err)
+
+	sessionB, err := signerB.MuSig2CreateSession(
+		0, locator, pubKeys, nil, nil, nil,
+	)
+	require.NoError(t, err)
+
+	haveAllA, err := signerA.MuSig2RegisterNonces(
+		sessionA.SessionID,
+		[][musig2.PubNonceSize]byte{sessionB.PublicNonce},
+	)
+	require.NoError(t, err)
+	require.True(t, haveAllA)
+
+	haveAllB, err := signerB.MuSig2RegisterNonces(
+		sessionB.SessionID,
+		[][musig2.PubNonceSize]byte{sessionA.PublicNonce},
+	)
+	require.NoError(t, err)
+	require.True(t, haveAllB)
+
+	var msg [32]byte
+	copy(msg[:], []byte("scripted signer interop test msg"))
+
+	sigA, err := signerA.MuSig2Sign(sessionA.SessionID, msg, false)
+	require.NoError(t, err)
+
+	sigB, err := signerB.MuSig2Sign(sessionB.SessionID, msg, false)
+	require.NoError(t, err)
+
+	finalSig, haveAllSigs, err := signerA.MuSig2CombineSig(
+		sessionA.SessionID, []*musig2.PartialSignature{sigB},
+	)
+	require.NoError(t, err)
+	require.True(t, haveAllSigs)
+
+	combinedKey, _, _, err := musig2.AggregateKeys(pubKeys, false)
+	require.NoError(t, err)
+	require.True(t, finalSig.Verify(msg[:], combinedKey.FinalKey))
+
+	req := <-signerA.MuSig2SignCh
+	require.Equal(t, sessionA.SessionID, req.SessionID)
+
+	_ = sigA
+}
+
+// TestScriptedInputSignerSignOutputRaw asserts that ComputeInputScript
+// produces a real witness that successfully spends a p2wkh output under
+// txscript.NewEngine, and that a SignOutputRaw call for the same input is
+// observable on SignOutputRawCh.
+func TestScriptedInputSignerSignOutputRaw(t *testing.T) {
+	locator := keychain.KeyLocator{Family: keychain.KeyFamilyMultiSig}
+
+	privKeys, pubKeys := genKeys(t, 1)
+	privKey, pubKey := privKeys[0], pubKeys[0]
+
+	pubKeyHash := btcutil.Hash160(pubKey.SerializeCompressed())
+
+	pkScript, err := txscript.NewScriptBuilder().
+		AddOp(txscript.OP_0).AddData(pubKeyHash).Script()
+	require.NoError(t, err)
+
+	witnessScript, err := txscript.NewScriptBuilder().
+		AddOp(txscript.OP_DUP).AddOp(txscript.OP_HASH160).
+		AddData(pubKeyHash).AddOp(txscript.OP_EQUALVERIFY).
+		AddOp(txscript.OP_CHECKSIG).Script()
+	require.NoError(t, err)
+
+	fundTx := wire.NewMsgTx(2)
+	fundTx.AddTxOut(&wire.TxOut{Value: 1_000_000, PkScript: pkScript})
+
+	op := wire.OutPoint{Hash: fundTx.TxHash(), Index: 0}
+	tx := sweepTx(op)
+
+	signDesc := &SignDescriptor{
+		KeyDesc:       keychain.KeyDescriptor{KeyLocator: locator},
+		WitnessScript: witnessScript,
+		Output:        fundTx.TxOut[0],
+		HashType:      txscript.SigHashAll,
+		SigHashes:     txscript.NewTxSigHashes(tx, nil),
+		InputIndex:    0,
+	}
+
+	signer := NewScriptedInputSigner(
+		map[keychain.KeyLocator]*btcec.PrivateKey{locator: privKey},
+	)
+	t.Cleanup(signer.Close)
+
+	script, err := signer.ComputeInputScript(tx, signDesc)
+	require.NoError(t, err)
+	tx.TxIn[0].Witness = script.Witness
+
+	prevOutFetcher := txscript.NewCannedPrevOutputFetcher(
+		pkScript, fundTx.TxOut[0].Value,
+	)
+	engine, err := txscript.NewEngine(
+		pkScript, tx, 0, txscript.StandardVerifyFlags, nil, nil,
+		fundTx.TxOut[0].Value, prevOutFetcher,
+	)
+	require.NoError(t, err)
+	require.NoError(t, engine.Execute())
+
+	sig, err := signer.SignOutputRaw(tx, signDesc)
+	require.NoError(t, err)
+
+	req := <-signer.SignOutputRawCh
+	require.Equal(t, tx, req.Tx)
+	_ = sig
+}
+
+// TestScriptedInputSignerTaprootKeySpend asserts that SignOutputRaw signs a
+// BIP-86 taproot key-path spend with the tweaked private key, producing a
+// signature that verifies against the output key under txscript.NewEngine.
+func TestScriptedInputSignerTaprootKeySpend(t *testing.T) {
+	locator := keychain.KeyLocator{Family: keychain.KeyFamilyMultiSig}
+
+	privKeys, pubKeys := genKeys(t, 1)
+	privKey, internalKey := privKeys[0], pubKeys[0]
+
+	outputKey := txscript.ComputeTaprootKeyNoScript(internalKey)
+
+	pkScript, err := txscript.PayToTaprootScript(outputKey)
+	require.NoError(t, err)
+
+	fundTx := wire.NewMsgTx(2)
+	fundTx.AddTxOut(&wire.TxOut{Value: 1_000_000, PkScript: pkScript})
+
+	op := wire.OutPoint{Hash: fundTx.TxHash(), Index: 0}
+	tx := sweepTx(op)
+
+	prevOutFetcher := txscript.NewCannedPrevOutputFetcher(
+		pkScript, fundTx.TxOut[0].Value,
+	)
+
+	signDesc := &SignDescriptor{
+		KeyDesc:           keychain.KeyDescriptor{KeyLocator: locator},
+		Output:            fundTx.TxOut[0],
+		HashType:          txscript.SigHashDefault,
+		SigHashes:         txscript.NewTxSigHashes(tx, prevOutFetcher),
+		InputIndex:        0,
+		PrevOutputFetcher: prevOutFetcher,
+		SignMethod:        TaprootKeySpendBIP0086SignMethod,
+	}
+
+	signer := NewScriptedInputSigner(
+		map[keychain.KeyLocator]*btcec.PrivateKey{locator: privKey},
+	)
+	t.Cleanup(signer.Close)
+
+	sig, err := signer.SignOutputRaw(tx, signDesc)
+	require.NoError(t, err)
+
+	tx.TxIn[0].Witness = wire.TxWitness{sig.Serialize()}
+
+	engine, err := txscript.NewEngine(
+		pkScript, tx, 0, txscript.StandardVerifyFlags, nil,
+		signDesc.SigHashes, fundTx.TxOut[0].Value, prevOutFetcher,
+	)
+	require.NoError(t, err)
+	require.NoError(t, engine.Execute())
+}
+
+// TestScriptedInputSignerQueueDoesNotBlock asserts that MuSig2Sign can be
+// called many times in a row while nobody reads MuSig2SignCh without the
+// caller ever blocking, since the unboundedQueue behind that channel never
+// blocks on send.
+func TestScriptedInputSignerQueueDoesNotBlock(t *testing.T) {
+	locator := keychain.KeyLocator{Family: keychain.KeyFamilyMultiSig}
+
+	privKeys, pubKeys := genKeys(t, 2)
+
+	signerA := NewScriptedInputSigner(
+		map[keychain.KeyLocator]*btcec.PrivateKey{locator: privKeys[0]},
+	)
+	signerB := NewScriptedInputSigner(
+		map[keychain.KeyLocator]*btcec.PrivateKey{locator: privKeys[1]},
+	)
+	t.Cleanup(signerA.Close)
+	t.Cleanup(signerB.Close)
+
+	const numCalls = 50
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		for i := 0; i < numCalls; i++ {
+			sessionA, err := signerA.MuSig2CreateSession(
+				0, locator, pubKeys, nil, nil, nil,
+			)
+			require.NoError(t, err)
+
+			sessionB, err := signerB.MuSig2CreateSession(
+				0, locator, pubKeys, nil, nil, nil,
+			)
+			require.NoError(t, err)
+
+			_, err = signerA.MuSig2RegisterNonces(
+				sessionA.SessionID,
+				[][musig2.PubNonceSize]byte{sessionB.PublicNonce},
+			)
+			require.NoError(t, err)
+
+			var msg [32]byte
+			copy(msg[:], []byte("queue regression test msg"))
+
+			_, err = signerA.MuSig2Sign(sessionA.SessionID, msg, false)
+			require.NoError(t, err)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("MuSig2Sign blocked, unboundedQueue did not unblock " +
+			"the caller")
+	}
+}
+
+// TestUnboundedQueueClose asserts that Close stops the forward goroutine
+// (no further items are ever delivered on out) and that it's safe to call
+// more than once, so a caller (e.g. ScriptedInputSigner.Close in a test's
+// cleanup) doesn't leak the goroutine backing each queue.
+func TestUnboundedQueueClose(t *testing.T) {
+	q := newUnboundedQueue[int]()
+
+	q.send(1)
+	require.Equal(t, 1, <-q.out)
+
+	q.Close()
+	require.NotPanics(t, q.Close)
+
+	q.send(2)
+
+	select {
+	case v := <-q.out:
+		t.Fatalf("received %d after Close, forward goroutine is "+
+			"still running", v)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
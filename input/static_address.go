@@ -0,0 +1,305 @@
+package input
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightningnetwork/lnd/lntypes"
+)
+
+const (
+	// StaticAddressExpiryScriptSize is the size of the script enforcing
+	// the CSV expiry path of a static address output:
+	//	OP_DATA_32 <client_pubkey (32)> OP_CHECKSIGVERIFY OP_DATA_4
+	//	<csv_blocks (4)> OP_CHECKSEQUENCEVERIFY
+	//
+	// The csv_blocks push is a fixed-width 4-byte little-endian value
+	// (rather than a minimally-encoded CScriptNum) so that this size is
+	// constant for every possible CSV value.
+	StaticAddressExpiryScriptSize = 1 + 32 + 1 + 1 + 4 + 1
+
+	// StaticAddressExpiryWitnessSize is the maximum size of a witness
+	// that spends the CSV expiry path of a static address output:
+	//	1 (num elements) + 1 (sig varint) + 64 (schnorr sig) +
+	//	1 (script varint) + StaticAddressExpiryScriptSize (script) +
+	//	1 (control block varint) + 33 (control block)
+	StaticAddressExpiryWitnessSize = 1 + 1 + 64 + 1 +
+		StaticAddressExpiryScriptSize + 1 + 33
+
+	// StaticAddressMultiSigWitnessSize is the maximum size of a witness
+	// that spends the key-spend (MuSig2) path of a static address
+	// output:
+	//	1 (num elements) + 1 (sig varint) + 64 (schnorr sig)
+	StaticAddressMultiSigWitnessSize = 1 + 1 + 64
+
+	// staticAddressNUMSHex is a point on the curve with no known discrete
+	// log, taken from BIP-0341's test vectors. It is used as the taproot
+	// internal key whenever a static address is not meant to expose a
+	// cooperative key-spend path.
+	staticAddressNUMSHex = "50929b74c1a04954b78b4b6035e97a5e078a5a0f2" +
+		"8ec96d547bfee9ace803ac0"
+)
+
+// StaticAddressNUMSKey parses and returns the well-known NUMS point used as
+// the internal key of a static address output that has no key-spend path.
+func StaticAddressNUMSKey() (*btcec.PublicKey, error) {
+	numsBytes, err := hex.DecodeString(staticAddressNUMSHex)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode NUMS point: %w", err)
+	}
+
+	return schnorr.ParsePubKey(numsBytes)
+}
+
+// StaticAddress houses the taproot script tree needed to spend a "static
+// address" output. The output can either be spent cooperatively via a
+// MuSig2 combined key (the key-spend path), or unilaterally by the client
+// after a CSV timeout has elapsed (the script-spend path).
+type StaticAddress struct {
+	// InternalPubKey is the taproot internal key of the output. This is
+	// either the NUMS point returned by StaticAddressNUMSKey, or the
+	// MuSig2 combined key of the server and client.
+	InternalPubKey *btcec.PublicKey
+
+	// ClientPubKey is the client's public key that must sign the expiry
+	// leaf.
+	ClientPubKey *btcec.PublicKey
+
+	// CSVExpiry is the number of blocks, relative to the confirmation
+	// height of the funding output, after which the client can sweep the
+	// output unilaterally.
+	CSVExpiry uint32
+
+	// ExpiryLeaf is the tapscript leaf enforcing the CSV expiry path.
+	ExpiryLeaf txscript.TapLeaf
+
+	// TapscriptTree is the tapscript tree made up of the single expiry
+	// leaf.
+	TapscriptTree *txscript.IndexedTapScriptTree
+
+	// TaprootKey is the resulting output key, i.e. InternalPubKey tweaked
+	// by the root hash of TapscriptTree.
+	TaprootKey *btcec.PublicKey
+}
+
+// expiryScript returns the tapscript leaf script that can be used to sweep a
+// static address output unilaterally after csvExpiry blocks have passed:
+//
+//	<clientPubKey> OP_CHECKSIGVERIFY <csvExpiry> OP_CHECKSEQUENCEVERIFY
+//
+// csvExpiry is pushed as a fixed-width 4-byte little-endian value rather
+// than via AddInt64, whose minimal CScriptNum encoding would otherwise vary
+// the resulting script's length depending on the CSV value.
+func expiryScript(clientPubKey *btcec.PublicKey,
+	csvExpiry uint32) ([]byte, error) {
+
+	var csvBytes [4]byte
+	binary.LittleEndian.PutUint32(csvBytes[:], csvExpiry)
+
+	builder := txscript.NewScriptBuilder()
+	builder.AddData(schnorr.SerializePubKey(clientPubKey))
+	builder.AddOp(txscript.OP_CHECKSIGVERIFY)
+	builder.AddData(csvBytes[:])
+	builder.AddOp(txscript.OP_CHECKSEQUENCEVERIFY)
+
+	return builder.Script()
+}
+
+// NewStaticAddress constructs the taproot script tree for a static address
+// output. internalKey is either the NUMS point from StaticAddressNUMSKey or
+// the MuSig2 combined key of the server and client, depending on whether a
+// cooperative key-spend path should be available.
+func NewStaticAddress(internalKey, clientPubKey *btcec.PublicKey,
+	csvExpiry uint32) (*StaticAddress, error) {
+
+	leafScript, err := expiryScript(clientPubKey, csvExpiry)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create expiry script: %w",
+			err)
+	}
+
+	if len(leafScript) != StaticAddressExpiryScriptSize {
+		return nil, fmt.Errorf("unexpected expiry script size: "+
+			"got %d, want %d", len(leafScript),
+			StaticAddressExpiryScriptSize)
+	}
+
+	expiryLeaf := txscript.NewBaseTapLeaf(leafScript)
+	tapscriptTree := txscript.AssembleTaprootScriptTree(expiryLeaf)
+
+	rootHash := tapscriptTree.RootNode.TapHash()
+	taprootKey := txscript.ComputeTaprootOutputKey(
+		internalKey, rootHash[:],
+	)
+
+	return &StaticAddress{
+		InternalPubKey: internalKey,
+		ClientPubKey:   clientPubKey,
+		CSVExpiry:      csvExpiry,
+		ExpiryLeaf:     expiryLeaf,
+		TapscriptTree:  tapscriptTree,
+		TaprootKey:     taprootKey,
+	}, nil
+}
+
+// controlBlockBytes returns the serialized control block needed to reveal
+// the expiry leaf of the given static address.
+func (a *StaticAddress) controlBlockBytes() ([]byte, error) {
+	idx, ok := a.TapscriptTree.LeafProofIndex[a.ExpiryLeaf.TapHash()]
+	if !ok {
+		return nil, fmt.Errorf("expiry leaf not found in tapscript " +
+			"tree")
+	}
+
+	proof := a.TapscriptTree.LeafMerkleProofs[idx]
+	controlBlock := proof.ToControlBlock(a.InternalPubKey)
+
+	return controlBlock.ToBytes()
+}
+
+// taprootStaticAddressMultiSig is a WitnessType that spends the key-spend
+// path of a static address output via the combined MuSig2 signature of the
+// server and the client. Its witness generator sets the sign descriptor's
+// SignMethod to TaprootKeySpendSignMethod and uses the signature returned by
+// the Signer's SignOutputRaw as the sole witness element, so that Signer must
+// return the combined MuSig2 signature for the output key.
+type taprootStaticAddressMultiSig struct {
+	address *StaticAddress
+}
+
+// TaprootStaticAddressMultiSig returns a WitnessType that sweeps the
+// key-spend path of the given static address output.
+func TaprootStaticAddressMultiSig(address *StaticAddress) WitnessType {
+	return &taprootStaticAddressMultiSig{address: address}
+}
+
+// String returns a human readable version of the WitnessType.
+func (t *taprootStaticAddressMultiSig) String() string {
+	return "taproot-static-address-multi-sig"
+}
+
+// WitnessGenerator returns a function that can be used to generate the
+// witness for the key-spend path of a static address output.
+func (t *taprootStaticAddressMultiSig) WitnessGenerator(signer Signer,
+	descriptor *SignDescriptor) WitnessGenerator {
+
+	return func(tx *wire.MsgTx, _ *txscript.TxSigHashes,
+		inputIndex int) (*Script, error) {
+
+		descCopy := *descriptor
+		desc := &descCopy
+		desc.InputIndex = inputIndex
+		desc.SignMethod = TaprootKeySpendSignMethod
+
+		sig, err := signer.SignOutputRaw(tx, desc)
+		if err != nil {
+			return nil, fmt.Errorf("unable to generate sig: %w",
+				err)
+		}
+
+		return &Script{
+			Witness: wire.TxWitness{sig.Serialize()},
+		}, nil
+	}
+}
+
+// SizeUpperBound returns the maximum size of the witness required to spend
+// the key-spend path of a static address output.
+func (t *taprootStaticAddressMultiSig) SizeUpperBound() (
+	lntypes.WeightUnit, bool, error) {
+
+	return lntypes.WeightUnit(StaticAddressMultiSigWitnessSize), false,
+		nil
+}
+
+// AddWeightEstimation adds the estimated size of the witness to the given
+// weight estimator.
+func (t *taprootStaticAddressMultiSig) AddWeightEstimation(
+	e *TxWeightEstimator) error {
+
+	e.AddTaprootKeySpendInput(txscript.SigHashDefault)
+
+	return nil
+}
+
+// taprootStaticAddressExpiry is a WitnessType that sweeps a static address
+// output through its CSV expiry leaf, once the client is allowed to spend
+// it unilaterally.
+type taprootStaticAddressExpiry struct {
+	address *StaticAddress
+}
+
+// TaprootStaticAddressExpiry returns a WitnessType that sweeps the expiry
+// path of the given static address output.
+func TaprootStaticAddressExpiry(address *StaticAddress) WitnessType {
+	return &taprootStaticAddressExpiry{address: address}
+}
+
+// String returns a human readable version of the WitnessType.
+func (t *taprootStaticAddressExpiry) String() string {
+	return "taproot-static-address-expiry"
+}
+
+// WitnessGenerator returns a function that can be used to generate the
+// witness that sweeps the expiry path of a static address output. The
+// returned closure sets the input's nSequence to the CSV expiry value, since
+// BIP-0112 requires it to be encoded there for OP_CHECKSEQUENCEVERIFY to
+// succeed.
+func (t *taprootStaticAddressExpiry) WitnessGenerator(signer Signer,
+	descriptor *SignDescriptor) WitnessGenerator {
+
+	return func(tx *wire.MsgTx, _ *txscript.TxSigHashes,
+		inputIndex int) (*Script, error) {
+
+		tx.TxIn[inputIndex].Sequence = t.address.CSVExpiry
+
+		leafScript := t.address.ExpiryLeaf.Script
+
+		descCopy := *descriptor
+		desc := &descCopy
+		desc.InputIndex = inputIndex
+		desc.WitnessScript = leafScript
+		desc.SignMethod = TaprootScriptSpendSignMethod
+
+		sig, err := signer.SignOutputRaw(tx, desc)
+		if err != nil {
+			return nil, fmt.Errorf("unable to generate sig: %w",
+				err)
+		}
+
+		controlBlockBytes, err := t.address.controlBlockBytes()
+		if err != nil {
+			return nil, fmt.Errorf("unable to serialize "+
+				"control block: %w", err)
+		}
+
+		return &Script{
+			Witness: wire.TxWitness{
+				sig.Serialize(), leafScript, controlBlockBytes,
+			},
+		}, nil
+	}
+}
+
+// SizeUpperBound returns the maximum size of the witness required to sweep
+// the expiry path of a static address output.
+func (t *taprootStaticAddressExpiry) SizeUpperBound() (
+	lntypes.WeightUnit, bool, error) {
+
+	return lntypes.WeightUnit(StaticAddressExpiryWitnessSize), false, nil
+}
+
+// AddWeightEstimation adds the estimated size of the witness to the given
+// weight estimator.
+func (t *taprootStaticAddressExpiry) AddWeightEstimation(
+	e *TxWeightEstimator) error {
+
+	e.AddTapscriptInput(StaticAddressExpiryWitnessSize)
+
+	return nil
+}
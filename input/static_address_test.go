@@ -0,0 +1,164 @@
+package input
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+// fundingTx returns a single output funding transaction paying to the given
+// taproot key, along with the outpoint of that output.
+func fundingTx(t *testing.T, taprootKey *btcec.PublicKey) (*wire.MsgTx,
+	wire.OutPoint) {
+
+	t.Helper()
+
+	pkScript, err := txscript.PayToTaprootScript(taprootKey)
+	require.NoError(t, err)
+
+	tx := wire.NewMsgTx(2)
+	tx.AddTxOut(&wire.TxOut{
+		Value:    1_000_000,
+		PkScript: pkScript,
+	})
+
+	op := wire.OutPoint{
+		Hash:  tx.TxHash(),
+		Index: 0,
+	}
+
+	return tx, op
+}
+
+// sweepTx returns a single input, single output transaction that spends the
+// given outpoint.
+func sweepTx(op wire.OutPoint) *wire.MsgTx {
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(&wire.TxIn{PreviousOutPoint: op})
+	tx.AddTxOut(&wire.TxOut{
+		Value:    900_000,
+		PkScript: []byte{txscript.OP_TRUE},
+	})
+
+	return tx
+}
+
+// TestStaticAddressMultiSigSweep asserts that the key-spend path of a static
+// address output produces a single element witness that respects the
+// declared size upper bound.
+func TestStaticAddressMultiSigSweep(t *testing.T) {
+	internalKey, err := StaticAddressNUMSKey()
+	require.NoError(t, err)
+
+	clientPriv, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+
+	addr, err := NewStaticAddress(internalKey, clientPriv.PubKey(), 1)
+	require.NoError(t, err)
+
+	fundTx, op := fundingTx(t, addr.TaprootKey)
+	tx := sweepTx(op)
+
+	sig, err := schnorr.Sign(clientPriv, make([]byte, chainhash.HashSize))
+	require.NoError(t, err)
+
+	signer := &MockInputSigner{}
+	signer.On("SignOutputRaw", tx, &SignDescriptor{
+		InputIndex: 0,
+		SignMethod: TaprootKeySpendSignMethod,
+	}).Return(sig, nil)
+
+	witnessType := TaprootStaticAddressMultiSig(addr)
+	genWitness := witnessType.WitnessGenerator(signer, &SignDescriptor{})
+
+	script, err := genWitness(tx, nil, 0)
+	require.NoError(t, err)
+	require.Len(t, script.Witness, 1)
+
+	upperBound, _, err := witnessType.SizeUpperBound()
+	require.NoError(t, err)
+	require.LessOrEqual(t, witnessSize(script.Witness),
+		uint64(upperBound))
+
+	_ = fundTx
+	signer.AssertExpectations(t)
+}
+
+// TestStaticAddressExpirySweep asserts that the expiry path of a static
+// address output sets the input's relative locktime and produces a three
+// element witness that respects the declared size upper bound.
+func TestStaticAddressExpirySweep(t *testing.T) {
+	internalKey, err := StaticAddressNUMSKey()
+	require.NoError(t, err)
+
+	clientPriv, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+
+	const csvExpiry = 52560
+
+	addr, err := NewStaticAddress(
+		internalKey, clientPriv.PubKey(), csvExpiry,
+	)
+	require.NoError(t, err)
+
+	fundTx, op := fundingTx(t, addr.TaprootKey)
+	tx := sweepTx(op)
+
+	sig, err := schnorr.Sign(clientPriv, make([]byte, chainhash.HashSize))
+	require.NoError(t, err)
+
+	signer := &MockInputSigner{}
+	signer.On("SignOutputRaw", tx, &SignDescriptor{
+		InputIndex:    0,
+		WitnessScript: addr.ExpiryLeaf.Script,
+		SignMethod:    TaprootScriptSpendSignMethod,
+	}).Return(sig, nil)
+
+	witnessType := TaprootStaticAddressExpiry(addr)
+	genWitness := witnessType.WitnessGenerator(signer, &SignDescriptor{})
+
+	script, err := genWitness(tx, nil, 0)
+	require.NoError(t, err)
+	require.Len(t, script.Witness, 3)
+	require.Equal(t, uint32(csvExpiry), tx.TxIn[0].Sequence)
+
+	upperBound, _, err := witnessType.SizeUpperBound()
+	require.NoError(t, err)
+	require.LessOrEqual(t, witnessSize(script.Witness),
+		uint64(upperBound))
+
+	_ = fundTx
+	signer.AssertExpectations(t)
+}
+
+// TestExpiryScriptFixedSize asserts that the expiry leaf script is exactly
+// StaticAddressExpiryScriptSize bytes for every CSV value, including ones
+// whose minimally-encoded CScriptNum representation would otherwise vary in
+// length (e.g. a single small-integer opcode for 0 through 16, or an extra
+// sign-extension byte for 128 and 32768).
+func TestExpiryScriptFixedSize(t *testing.T) {
+	clientPriv, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+
+	csvValues := []uint32{0, 1, 6, 127, 128, 32767, 32768, 52560}
+	for _, csv := range csvValues {
+		script, err := expiryScript(clientPriv.PubKey(), csv)
+		require.NoError(t, err)
+		require.Len(t, script, StaticAddressExpiryScriptSize)
+	}
+}
+
+// witnessSize returns the serialized byte size of a witness stack.
+func witnessSize(witness wire.TxWitness) uint64 {
+	var size uint64
+	for _, elem := range witness {
+		size += uint64(len(elem)) + 1
+	}
+
+	return size + 1
+}